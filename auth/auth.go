@@ -0,0 +1,129 @@
+// Package auth реализует выдачу и проверку JWT-токенов оркестратора:
+// пользовательские токены, к которым привязываются задачи их владельца, и
+// отдельные токены с ролью worker для маршрутов, которыми пользуются
+// воркеры-агенты, чтобы браузерный клиент не мог подменить результат.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// RoleUser - токен обычного клиента, видящего только свои задачи
+// RoleWorker - токен воркера-агента, дающий доступ к раздаче подзадач
+const (
+	RoleUser   = "user"
+	RoleWorker = "worker"
+)
+
+// ErrMissingToken возвращается, если запрос не содержит заголовка Authorization
+var ErrMissingToken = errors.New("отсутствует токен авторизации")
+
+// ErrInvalidToken возвращается, если токен не прошёл проверку подписи или истёк
+var ErrInvalidToken = errors.New("недействительный токен авторизации")
+
+// tokenTTL - срок действия выдаваемых токенов
+const tokenTTL = 24 * time.Hour
+
+// Claims - содержимое JWT-токена оркестратора
+type Claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// contextKey - приватный тип ключа контекста, чтобы не пересекаться с другими пакетами
+type contextKey int
+
+const claimsContextKey contextKey = 0
+
+// Issuer подписывает и проверяет токены оркестратора общим секретом
+type Issuer struct {
+	secret []byte
+}
+
+// NewIssuer создаёт Issuer, подписывающий токены указанным секретом
+func NewIssuer(secret string) *Issuer {
+	return &Issuer{secret: []byte(secret)}
+}
+
+// Issue выдаёт подписанный токен для subject (ID пользователя или воркера) с указанной ролью
+func (i *Issuer) Issue(subject, role string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokenTTL)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret)
+}
+
+// Parse проверяет подпись и срок действия токена и возвращает его claims
+func (i *Issuer) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		// Секрет общий - алгоритм должен быть симметричным, иначе токен,
+		// подписанный чужим ключом под asymmetric alg, мог бы пройти проверку
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return i.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// fromHeader извлекает и проверяет токен из заголовка Authorization: Bearer <token>
+func (i *Issuer) fromHeader(r *http.Request) (*Claims, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, ErrMissingToken
+	}
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return nil, ErrInvalidToken
+	}
+	return i.Parse(parts[1])
+}
+
+// Require оборачивает обработчик, требуя валидный токен с одной из перечисленных
+// ролей (без ролей - допускается любая). Claims кладутся в контекст запроса и
+// доступны обработчику через FromContext.
+func (i *Issuer) Require(handler http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := i.fromHeader(r)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if len(roles) > 0 && !roleAllowed(claims.Role, roles) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(r.Context(), claimsContextKey, claims)))
+	}
+}
+
+func roleAllowed(role string, allowed []string) bool {
+	for _, a := range allowed {
+		if role == a {
+			return true
+		}
+	}
+	return false
+}
+
+// FromContext возвращает claims, помещённые в контекст запроса обработчиком Require
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*Claims)
+	return claims, ok
+}
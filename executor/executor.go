@@ -0,0 +1,200 @@
+// Package executor раскладывает AST арифметического выражения на атомарные
+// подзадачи вида "a OP b" с учётом зависимостей между ними, чтобы воркеры
+// получали отдельные операции, а не выражение целиком. Это позволяет
+// вычислять независимые поддеревья параллельно.
+package executor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/M0TIVATI0N/Bober/parser"
+)
+
+// Unit - атомарная подзадача "a OP b", готовая к выполнению воркером
+type Unit struct {
+	ID         string  `json:"id"`
+	TaskID     string  `json:"task_id"`
+	Operator   string  `json:"operator"`
+	Left       float64 `json:"left"`
+	Right      float64 `json:"right"`
+	Duration   int     `json:"duration"`
+	ClaimToken string  `json:"claim_token,omitempty"` // выдаётся воркеру при раздаче, подтверждает право прислать результат
+}
+
+// unitNode - операция внутри плана вычисления вместе со своими операндами,
+// которые либо уже известны (литералы), либо ссылаются на результат другого узла
+type unitNode struct {
+	id         string
+	operator   string
+	duration   int
+	leftVal    float64
+	leftRef    string
+	rightVal   float64
+	rightRef   string
+	value      float64
+	done       bool
+	dispatched bool
+}
+
+// Plan - граф зависимостей подзадач одного выражения
+type Plan struct {
+	taskID string
+	nodes  map[string]*unitNode
+	root   string // пусто, если выражение - одно число без операций
+	done   bool
+	result float64
+}
+
+// NewPlan строит план вычисления по AST выражения, используя durations для
+// определения длительности каждой операции по её оператору
+func NewPlan(taskID string, root *parser.Node, durations map[string]int) (*Plan, error) {
+	p := &Plan{taskID: taskID, nodes: make(map[string]*unitNode)}
+	counter := 0
+
+	var build func(n *parser.Node) (value float64, isConst bool, ref string, err error)
+	build = func(n *parser.Node) (float64, bool, string, error) {
+		switch n.Kind {
+		case parser.KindNumber:
+			return n.Value, true, "", nil
+
+		case parser.KindUnaryMinus:
+			return build(&parser.Node{
+				Kind:     parser.KindBinary,
+				Operator: "-",
+				Left:     &parser.Node{Kind: parser.KindNumber, Value: 0},
+				Right:    n.Left,
+			})
+
+		case parser.KindBinary:
+			duration, ok := durations[n.Operator]
+			if !ok {
+				return 0, false, "", fmt.Errorf("нет длительности для оператора %q", n.Operator)
+			}
+
+			leftVal, leftConst, leftRef, err := build(n.Left)
+			if err != nil {
+				return 0, false, "", err
+			}
+			rightVal, rightConst, rightRef, err := build(n.Right)
+			if err != nil {
+				return 0, false, "", err
+			}
+
+			counter++
+			id := fmt.Sprintf("%s-%d", taskID, counter)
+			node := &unitNode{id: id, operator: n.Operator, duration: duration}
+			if leftConst {
+				node.leftVal = leftVal
+			} else {
+				node.leftRef = leftRef
+			}
+			if rightConst {
+				node.rightVal = rightVal
+			} else {
+				node.rightRef = rightRef
+			}
+			p.nodes[id] = node
+			return 0, false, id, nil
+
+		default:
+			return 0, false, "", fmt.Errorf("неизвестный вид узла AST")
+		}
+	}
+
+	value, isConst, ref, err := build(root)
+	if err != nil {
+		return nil, err
+	}
+	if isConst {
+		p.done = true
+		p.result = value
+		return p, nil
+	}
+	p.root = ref
+	return p, nil
+}
+
+// Done сообщает, вычислен ли уже финальный результат выражения
+func (p *Plan) Done() (float64, bool) {
+	return p.result, p.done
+}
+
+// EstimatedCost возвращает сумму длительностей всех операций плана в
+// секундах - используется планировщиком как оценка стоимости задачи
+func (p *Plan) EstimatedCost() int {
+	total := 0
+	for _, node := range p.nodes {
+		total += node.duration
+	}
+	return total
+}
+
+// operandReady проверяет, что операнд, заданный ссылкой на другой узел, уже вычислен
+func (p *Plan) operandReady(ref string) bool {
+	if ref == "" {
+		return true
+	}
+	node, ok := p.nodes[ref]
+	return ok && node.done
+}
+
+func (p *Plan) operandValue(val float64, ref string) float64 {
+	if ref == "" {
+		return val
+	}
+	return p.nodes[ref].value
+}
+
+// Next возвращает подзадачи, чьи операнды уже известны и которые ещё не были
+// отправлены воркеру
+func (p *Plan) Next() []Unit {
+	var ready []Unit
+	for _, id := range p.sortedIDs() {
+		node := p.nodes[id]
+		if node.dispatched || node.done {
+			continue
+		}
+		if !p.operandReady(node.leftRef) || !p.operandReady(node.rightRef) {
+			continue
+		}
+		node.dispatched = true
+		ready = append(ready, Unit{
+			ID:       node.id,
+			TaskID:   p.taskID,
+			Operator: node.operator,
+			Left:     p.operandValue(node.leftVal, node.leftRef),
+			Right:    p.operandValue(node.rightVal, node.rightRef),
+			Duration: node.duration,
+		})
+	}
+	return ready
+}
+
+// sortedIDs возвращает ID узлов в детерминированном порядке, чтобы обход плана был стабильным
+func (p *Plan) sortedIDs() []string {
+	ids := make([]string, 0, len(p.nodes))
+	for id := range p.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Resolve принимает результат подзадачи unitID. Если это была последняя
+// оставшаяся операция, возвращает итоговый результат выражения
+func (p *Plan) Resolve(unitID string, result float64) (float64, bool) {
+	node, ok := p.nodes[unitID]
+	if !ok {
+		return 0, false
+	}
+	node.value = result
+	node.done = true
+
+	if unitID == p.root {
+		p.done = true
+		p.result = result
+		return result, true
+	}
+	return 0, false
+}
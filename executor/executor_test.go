@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/M0TIVATI0N/Bober/parser"
+)
+
+func durations() map[string]int {
+	return map[string]int{"+": 2, "-": 2, "*": 4, "/": 4}
+}
+
+func TestNewPlanSingleNumberIsDoneImmediately(t *testing.T) {
+	ast, err := parser.Parse("7")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	plan, err := NewPlan("task-1", ast, durations())
+	if err != nil {
+		t.Fatalf("NewPlan вернул ошибку: %v", err)
+	}
+	result, done := plan.Done()
+	if !done || result != 7 {
+		t.Fatalf("ожидался немедленный результат 7, получили result=%v done=%v", result, done)
+	}
+	if units := plan.Next(); len(units) != 0 {
+		t.Fatalf("готовый план не должен выдавать подзадачи, получили %v", units)
+	}
+}
+
+func TestPlanDependencyOrder(t *testing.T) {
+	// (1 + 2) * 3 - умножение не должно быть готово, пока не вычислено сложение
+	ast, err := parser.Parse("(1 + 2) * 3")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	plan, err := NewPlan("task-1", ast, durations())
+	if err != nil {
+		t.Fatalf("NewPlan вернул ошибку: %v", err)
+	}
+	if _, done := plan.Done(); done {
+		t.Fatalf("план с операциями не должен быть готов сразу")
+	}
+
+	first := plan.Next()
+	if len(first) != 1 {
+		t.Fatalf("ожидалась ровно одна готовая подзадача (сложение), получили %d", len(first))
+	}
+	addUnit := first[0]
+	if addUnit.Operator != "+" || addUnit.Left != 1 || addUnit.Right != 2 {
+		t.Fatalf("ожидалась подзадача 1 + 2, получили %+v", addUnit)
+	}
+
+	// Умножение ещё не готово, пока сложение не разрешено
+	if units := plan.Next(); len(units) != 0 {
+		t.Fatalf("умножение не должно быть готово до разрешения сложения, получили %v", units)
+	}
+
+	result, done := plan.Resolve(addUnit.ID, 3)
+	if done {
+		t.Fatalf("план не должен завершиться после промежуточной подзадачи")
+	}
+	_ = result
+
+	second := plan.Next()
+	if len(second) != 1 {
+		t.Fatalf("ожидалась ровно одна готовая подзадача (умножение), получили %d", len(second))
+	}
+	mulUnit := second[0]
+	if mulUnit.Operator != "*" || mulUnit.Left != 3 || mulUnit.Right != 3 {
+		t.Fatalf("ожидалась подзадача 3 * 3, получили %+v", mulUnit)
+	}
+
+	result, done = plan.Resolve(mulUnit.ID, 9)
+	if !done || result != 9 {
+		t.Fatalf("план должен завершиться результатом 9, получили result=%v done=%v", result, done)
+	}
+}
+
+func TestPlanEstimatedCostSumsDurations(t *testing.T) {
+	ast, err := parser.Parse("1 + 2 * 3")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	plan, err := NewPlan("task-1", ast, durations())
+	if err != nil {
+		t.Fatalf("NewPlan вернул ошибку: %v", err)
+	}
+	if cost := plan.EstimatedCost(); cost != 6 { // 2 (+) + 4 (*)
+		t.Fatalf("ожидалась суммарная стоимость 6, получили %d", cost)
+	}
+}
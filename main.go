@@ -1,32 +1,52 @@
 package main
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/M0TIVATI0N/Bober/auth"
+	"github.com/M0TIVATI0N/Bober/executor"
+	"github.com/M0TIVATI0N/Bober/metrics"
+	"github.com/M0TIVATI0N/Bober/parser"
+	"github.com/M0TIVATI0N/Bober/scheduler"
+	"github.com/M0TIVATI0N/Bober/store"
+	"github.com/M0TIVATI0N/Bober/worker"
 )
 
-// Task представляет структуру задачи
-type Task struct {
-	ID         int       `json:"id"`                   // Уникальный идентификатор задачи
-	Expression string    `json:"expression"`           // Выражение для вычисления
-	Status     string    `json:"status"`               // Статус задачи (pending, in_progress, completed)
-	Result     float64   `json:"result,omitempty"`     // Результат вычисления
-	StartTime  time.Time `json:"start_time,omitempty"` // Время начала выполнения задачи
-}
+// logger - структурированный логгер оркестратора; каждая запись помечается
+// относящимися к ней task_id и worker_id, если они известны
+var logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+const (
+	reaperInterval     = 2 * time.Second  // как часто проверяются упавшие воркеры и просроченные подзадачи
+	staleWorkerTimeout = 15 * time.Second // воркер считается упавшим, если за это время нет heartbeat
+	unitTimeoutFactor  = 3                // во сколько раз таймаут подзадачи (отсчитываемый от StartTime) больше заявленной Duration операции
+	defaultClientQuota = 20               // сколько заявок от одного клиента может одновременно ждать в очереди
+	resolvedUnitTTL    = 2 * time.Minute  // как долго помнится claim-токен, принявший результат - покрывает типичное окно повторной доставки
+
+	devJWTSecret    = "bober-dev-secret"        // используется, если BOBER_JWT_SECRET не задан - только для локальной разработки
+	devWorkerSecret = "bober-dev-worker-secret" // используется, если BOBER_WORKER_SECRET не задан - только для локальной разработки
+)
 
 // TaskRequest представляет структуру запроса на создание задачи
 type TaskRequest struct {
-	Expression string `json:"expression"` // Выражение для вычисления
+	Expression string `json:"expression"`         // Выражение для вычисления
+	Priority   int    `json:"priority,omitempty"` // Явный приоритет заявки, больше - важнее
 }
 
 // TaskResponse представляет структуру ответа с ID задачи
 type TaskResponse struct {
-	ID int `json:"id" ` // Уникальный идентификатор задачи
+	ID string `json:"id"` // Уникальный идентификатор задачи (UUID)
 }
 
 // Operation представляет структуру операции
@@ -35,17 +55,179 @@ type Operation struct {
 	Duration int    `json:"duration"`  // Продолжительность выполнения операции (в секундах)
 }
 
+// ResultSubmission представляет результат выполнения одной подзадачи, присылаемый воркером
+type ResultSubmission struct {
+	TaskID     string  `json:"task_id"`     // ID задачи, которой принадлежит подзадача
+	UnitID     string  `json:"unit_id"`     // ID подзадачи в рамках плана вычисления
+	Result     float64 `json:"result"`      // Результат выполнения операции
+	ClaimToken string  `json:"claim_token"` // Токен, выданный вместе с подзадачей - подтверждает, что результат шлёт тот же воркер
+}
+
+// LoginRequest представляет запрос на выдачу токена авторизации
+type LoginRequest struct {
+	UserID       string `json:"user_id,omitempty"`       // Желаемый идентификатор пользователя; если не задан, генерируется UUID
+	WorkerSecret string `json:"worker_secret,omitempty"` // Общий секрет воркеров - наличие выдаёт токен с ролью worker вместо user
+}
+
+// LoginResponse представляет ответ с выданным токеном
+type LoginResponse struct {
+	Token string `json:"token"`
+	Role  string `json:"role"`
+}
+
+// inFlightUnit - подзадача, уже выданная воркеру и ожидающая результата
+type inFlightUnit struct {
+	unit      executor.Unit
+	workerID  string
+	startTime time.Time
+}
+
+// resolvedUnit запоминает, каким claim-токеном подзадача была успешно
+// завершена. Нужен, чтобы отличить повторную (at-least-once) доставку уже
+// принятого результата - воркер, не увидевший свой 204 из-за потерянного
+// ответа, шлёт его снова с тем же токеном - от результата, присланного после
+// того, как подзадачу забрали и выдали заново (токен уже не совпадёт).
+type resolvedUnit struct {
+	claimToken string
+	resolvedAt time.Time
+}
+
 var (
-	tasks      []Task         // Список задач
-	tasksMutex sync.Mutex     // Мьютекс для защиты списка задач
+	taskStore      store.TaskStore                         // Хранилище задач (в памяти или персистентное)
+	workerRegistry = worker.NewRegistry()                  // Реестр зарегистрированных воркеров
+	taskScheduler  = scheduler.NewScheduler(clientQuota()) // Очередь ожидающих задач с приоритизацией и честностью
+	tokenIssuer    = auth.NewIssuer(jwtSecret())           // Выдача и проверка JWT-токенов пользователей и воркеров
+
+	execMutex     sync.Mutex                    // Защищает planByTask, pendingUnits, inFlightUnits и resolvedUnits
+	planByTask    = map[string]*executor.Plan{} // Планы вычисления, по одному на незавершённую задачу
+	pendingUnits  []executor.Unit               // Очередь атомарных подзадач, готовых к выполнению
+	inFlightUnits = map[string]inFlightUnit{}   // Подзадачи, уже розданные воркерам
+	resolvedUnits = map[string]resolvedUnit{}   // Недавно завершённые подзадачи, по claim-токену, принявшему результат
+
 	operations = []Operation{ // Список доступных операций
 		{"+", 2}, {"-", 2}, {"*", 4}, {"/", 4},
 	}
-	taskIDCounter = 0 // Счетчик ID задач
 )
 
+// clientQuota читает максимальное число одновременных заявок от одного
+// клиента из окружения, по умолчанию - defaultClientQuota
+func clientQuota() int {
+	raw := os.Getenv("BOBER_CLIENT_QUOTA")
+	if raw == "" {
+		return defaultClientQuota
+	}
+	quota, err := strconv.Atoi(raw)
+	if err != nil {
+		return defaultClientQuota
+	}
+	return quota
+}
+
+// jwtSecret читает секрет подписи токенов из окружения; в продакшене
+// BOBER_JWT_SECRET обязателен, иначе используется небезопасный секрет для разработки
+func jwtSecret() string {
+	if secret := os.Getenv("BOBER_JWT_SECRET"); secret != "" {
+		return secret
+	}
+	logger.Warn("BOBER_JWT_SECRET не задан, используется секрет для разработки - не для продакшена")
+	return devJWTSecret
+}
+
+// workerSecret читает общий секрет, которым воркеры подтверждают своё право
+// на токен с ролью worker при входе через /login
+func workerSecret() string {
+	if secret := os.Getenv("BOBER_WORKER_SECRET"); secret != "" {
+		return secret
+	}
+	logger.Warn("BOBER_WORKER_SECRET не задан, используется секрет для разработки - не для продакшена")
+	return devWorkerSecret
+}
+
+// clientIDFor определяет идентификатор клиента для заявки: subject
+// аутентифицированного токена. Раньше принимался самодекларируемый client_id
+// из тела запроса, но это позволяло одному пользователю обходить квоту и штраф
+// за честность, просто меняя это поле от запроса к запросу.
+func clientIDFor(claims *auth.Claims) string {
+	return claims.Subject
+}
+
+// operationDurations возвращает длительности операций в виде map для executor.NewPlan
+func operationDurations() map[string]int {
+	durations := make(map[string]int, len(operations))
+	for _, op := range operations {
+		durations[op.Operator] = op.Duration
+	}
+	return durations
+}
+
+// buildPlan разбирает выражение и строит план его вычисления по подзадачам
+func buildPlan(taskID, expression string) (*executor.Plan, error) {
+	ast, err := parser.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return executor.NewPlan(taskID, ast, operationDurations())
+}
+
+// registerPlan запоминает план незавершённой задачи, чтобы её подзадачи можно
+// было раздать воркерам, когда до неё дойдёт очередь в планировщике. Должен
+// вызываться до того, как задача станет видна заявителям (taskScheduler.Push),
+// иначе воркер может забрать задачу раньше, чем для неё появится план, и она
+// зависнет в in_progress без единой розданной подзадачи.
+func registerPlan(taskID string, plan *executor.Plan) {
+	execMutex.Lock()
+	defer execMutex.Unlock()
+	planByTask[taskID] = plan
+}
+
+// unregisterPlan отменяет регистрацию плана, сделанную registerPlan - нужен,
+// чтобы откатить её, если задачу не удалось поставить в очередь или сохранить
+func unregisterPlan(taskID string) {
+	execMutex.Lock()
+	defer execMutex.Unlock()
+	delete(planByTask, taskID)
+}
+
+// login выдаёт подписанный JWT-токен. Обычный клиент получает токен с ролью
+// user под указанным (или сгенерированным) ID. Воркер получает токен с ролью
+// worker, только предъявив общий worker_secret - это не даёт браузерному
+// клиенту самому себе выписать доступ к worker-маршрутам.
+func login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	role := auth.RoleUser
+	subject := req.UserID
+	if req.WorkerSecret != "" {
+		// Сравнение за постоянное время - секрет общий для всех воркеров, и
+		// обычное != раскрывало бы его побайтово через тайминг ответа
+		if subtle.ConstantTimeCompare([]byte(req.WorkerSecret), []byte(workerSecret())) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		role = auth.RoleWorker
+		subject = uuid.NewString()
+	} else if subject == "" {
+		subject = uuid.NewString()
+	}
+
+	token, err := tokenIssuer.Issue(subject, role)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LoginResponse{Token: token, Role: role})
+}
+
 // addTask обрабатывает запрос на добавление новой задачи
 func addTask(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+
 	var taskReq TaskRequest
 	err := json.NewDecoder(r.Body).Decode(&taskReq)
 	if err != nil {
@@ -53,38 +235,95 @@ func addTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Добавление задачи в список
-	tasksMutex.Lock()
-	defer tasksMutex.Unlock()
-	taskIDCounter++
-	newTask := Task{
-		ID:         taskIDCounter,
+	taskID := uuid.NewString()
+
+	// Разбор и валидация выражения - некорректные выражения отклоняются до постановки в очередь
+	plan, err := buildPlan(taskID, taskReq.Expression)
+	if err != nil {
+		metrics.ExpressionParseErrors.Inc()
+		http.Error(w, "Invalid expression: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	newTask := store.Task{
+		ID:         taskID,
 		Expression: taskReq.Expression,
 		Status:     "pending",
+		CreatedAt:  time.Now(),
+		ClientID:   clientIDFor(claims),
+		Priority:   taskReq.Priority,
+		OwnerID:    claims.Subject,
 	}
-	tasks = append(tasks, newTask)
+
+	if result, done := plan.Done(); done {
+		// Выражение без операций (например, одно число) - результат известен сразу
+		newTask.Status = "completed"
+		newTask.Result = result
+	} else {
+		// План регистрируется до постановки в очередь, иначе воркер может
+		// забрать задачу раньше, чем для неё появится план, и она зависнет
+		// в in_progress без единой розданной подзадачи
+		registerPlan(taskID, plan)
+		if err := taskScheduler.Push(taskID, newTask.ClientID, newTask.Priority, plan.EstimatedCost()); err != nil {
+			unregisterPlan(taskID)
+			http.Error(w, "Too many pending tasks for this client", http.StatusTooManyRequests)
+			return
+		}
+		metrics.QueueDepth.Set(float64(taskScheduler.Len()))
+	}
+
+	if err := taskStore.Create(newTask); err != nil {
+		if newTask.Status == "pending" {
+			unregisterPlan(taskID)
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.TasksTotal.WithLabelValues(newTask.Status).Inc()
 
 	// Ответ с ID задачи
-	resp := TaskResponse{ID: taskIDCounter}
+	resp := TaskResponse{ID: taskID}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
-// getTaskStatus обрабатывает запрос на получение статуса задачи
+// getTaskStatus обрабатывает запрос на получение статуса задачи. Задача
+// отдаётся только её владельцу - чужой ID отвечает так же, как несуществующий,
+// чтобы не раскрывать факт существования задачи.
 func getTaskStatus(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
 	taskID := r.URL.Query().Get("id")
 
-	tasksMutex.Lock()
-	defer tasksMutex.Unlock()
-	for _, task := range tasks {
-		if strconv.Itoa(task.ID) == taskID {
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(task)
-			return
+	task, err := taskStore.Get(taskID)
+	if err != nil || task.OwnerID != claims.Subject {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
+
+// listMyTasks обрабатывает запрос на получение всех задач, принадлежащих вызывающему
+func listMyTasks(w http.ResponseWriter, r *http.Request) {
+	claims, _ := auth.FromContext(r.Context())
+
+	all, err := taskStore.List()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	mine := make([]store.Task, 0, len(all))
+	for _, task := range all {
+		if task.OwnerID == claims.Subject {
+			mine = append(mine, task)
 		}
 	}
 
-	http.NotFound(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mine)
 }
 
 // getOperations обрабатывает запрос на получение списка операций
@@ -93,46 +332,177 @@ func getOperations(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(operations)
 }
 
-// getTaskForExecution обрабатывает запрос на получение задачи для выполнения
+// getTaskForExecution обрабатывает запрос воркера на получение подзадачи для
+// выполнения. Вместо выражения целиком воркер получает атомарную операцию "a OP b".
 func getTaskForExecution(w http.ResponseWriter, r *http.Request) {
-	// Здесь можно реализовать логику приоритизации задач на основе некоторых критериев
-	// Для простоты выберем первую невыполненную задачу
-	tasksMutex.Lock()
-	defer tasksMutex.Unlock()
-	for i, task := range tasks {
-		if task.Status == "pending" {
-			tasks[i].Status = "in_progress"
-			tasks[i].StartTime = time.Now()
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(task)
+	workerID := r.URL.Query().Get("worker_id")
+	if workerID == "" {
+		http.Error(w, "worker_id is required", http.StatusBadRequest)
+		return
+	}
+	if !workerRegistry.Known(workerID) {
+		http.Error(w, "unknown worker, register first", http.StatusBadRequest)
+		return
+	}
+
+	execMutex.Lock()
+	unitsAvailable := len(pendingUnits) > 0
+	execMutex.Unlock()
+
+	if !unitsAvailable {
+		// Очередь готовых подзадач пуста - забираем из планировщика следующую
+		// по приоритету задачу и раскрываем её первые готовые подзадачи
+		entry, ok := taskScheduler.Pop()
+		if !ok {
+			http.NotFound(w, r)
 			return
 		}
+		if err := taskStore.UpdateStatus(entry.TaskID, "in_progress"); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		metrics.QueueDepth.Set(float64(taskScheduler.Len()))
+
+		execMutex.Lock()
+		if plan, ok := planByTask[entry.TaskID]; ok {
+			pendingUnits = append(pendingUnits, plan.Next()...)
+		}
+		execMutex.Unlock()
+	}
+
+	execMutex.Lock()
+	if len(pendingUnits) == 0 {
+		execMutex.Unlock()
+		http.NotFound(w, r)
+		return
 	}
+	unit := pendingUnits[0]
+	pendingUnits = pendingUnits[1:]
+	unit.ClaimToken = uuid.NewString() // новый токен при каждой раздаче - старый токен перестаёт быть валиден при переназначении
+	inFlightUnits[unit.ID] = inFlightUnit{unit: unit, workerID: workerID, startTime: time.Now()}
+	execMutex.Unlock()
+
+	workerRegistry.SetCurrentUnit(workerID, unit.ID)
 
-	http.NotFound(w, r)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(unit)
 }
 
-// handleResult обрабатывает результат выполнения задачи
+// handleResult обрабатывает результат выполнения подзадачи. Принимается только
+// результат, сопровождённый claim-токеном, выданным текущей раздачей этой
+// подзадачи - это делает повторную (at-least-once) доставку от воркера
+// безопасной и отклоняет результат от воркера, у которого подзадачу уже
+// отобрали из-за таймаута или краха (reapStaleUnits выдаёт её заново с новым токеном).
+// Если тем же токеном подзадача уже была принята, запрос считается повторной
+// доставкой уже применённого результата и подтверждается успехом, а не 409 -
+// иначе воркер, не увидевший свой 204 из-за потерянного ответа, решит, что
+// результат отклонён, и поднимет ложную тревогу.
 func handleResult(w http.ResponseWriter, r *http.Request) {
-	var task Task
-	err := json.NewDecoder(r.Body).Decode(&task)
+	var submission ResultSubmission
+	err := json.NewDecoder(r.Body).Decode(&submission)
 	if err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
 
-	tasksMutex.Lock()
-	defer tasksMutex.Unlock()
-	for i, t := range tasks {
-		if t.ID == task.ID {
-			tasks[i] = task
-			break
+	execMutex.Lock()
+	if resolved, ok := resolvedUnits[submission.UnitID]; ok {
+		execMutex.Unlock()
+		if resolved.claimToken == submission.ClaimToken {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			http.Error(w, "Unit not claimed by this token - it was already resolved or reassigned", http.StatusConflict)
+		}
+		return
+	}
+	plan, ok := planByTask[submission.TaskID]
+	if !ok {
+		execMutex.Unlock()
+		http.Error(w, "Task already completed or unknown", http.StatusConflict)
+		return
+	}
+	inflight, ok := inFlightUnits[submission.UnitID]
+	if !ok || inflight.unit.ClaimToken != submission.ClaimToken {
+		execMutex.Unlock()
+		http.Error(w, "Unit not claimed by this token - it was already resolved or reassigned", http.StatusConflict)
+		return
+	}
+	assignedWorker := inflight.workerID
+	delete(inFlightUnits, submission.UnitID)
+	resolvedUnits[submission.UnitID] = resolvedUnit{claimToken: submission.ClaimToken, resolvedAt: time.Now()}
+	result, done := plan.Resolve(submission.UnitID, submission.Result)
+	if done {
+		delete(planByTask, submission.TaskID)
+	} else {
+		pendingUnits = append(pendingUnits, plan.Next()...)
+	}
+	execMutex.Unlock()
+
+	if assignedWorker != "" {
+		workerRegistry.SetCurrentUnit(assignedWorker, "")
+	}
+
+	if done {
+		if err := taskStore.SetResult(submission.TaskID, result); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		metrics.TasksTotal.WithLabelValues("completed").Inc()
+		if task, err := taskStore.Get(submission.TaskID); err == nil && !task.StartTime.IsZero() {
+			metrics.TaskDuration.Observe(time.Since(task.StartTime).Seconds())
 		}
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// registerWorkerResponse представляет ответ на регистрацию воркера
+type registerWorkerResponse struct {
+	WorkerID string `json:"worker_id"`
+}
+
+// registerWorker обрабатывает запрос на регистрацию нового воркера-агента
+func registerWorker(w http.ResponseWriter, r *http.Request) {
+	id := workerRegistry.Register()
+	metrics.WorkersActive.Set(float64(len(workerRegistry.List())))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registerWorkerResponse{WorkerID: id})
+}
+
+// heartbeat обрабатывает периодический сигнал живости от воркера
+func heartbeat(w http.ResponseWriter, r *http.Request) {
+	workerID := r.URL.Query().Get("worker_id")
+	if err := workerRegistry.Heartbeat(workerID); err != nil {
+		http.Error(w, "Unknown worker", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listWorkers обрабатывает запрос на получение списка живых воркеров и их текущих подзадач
+func listWorkers(w http.ResponseWriter, r *http.Request) {
+	stale := make(map[string]bool)
+	for _, wk := range workerRegistry.Stale(staleWorkerTimeout) {
+		stale[wk.ID] = true
+	}
+	live := make([]worker.Worker, 0)
+	for _, wk := range workerRegistry.List() {
+		if !stale[wk.ID] {
+			live = append(live, wk)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(live)
+}
+
+// listQueue обрабатывает запрос на получение упорядоченного списка ожидающих
+// задач для наблюдаемости за планировщиком
+func listQueue(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(taskScheduler.Snapshot())
+}
+
 // indexHandler обрабатывает запрос на главную страницу
 func indexHandler(w http.ResponseWriter, r *http.Request) {
 	htmlBytes, err := ioutil.ReadFile("index.html")
@@ -145,15 +515,128 @@ func indexHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write(htmlBytes)
 }
 
+// newTaskStore выбирает бэкенд хранилища задач: персистентный BuntDB, если
+// задан путь к файлу базы данных, иначе хранилище в памяти
+func newTaskStore() store.TaskStore {
+	path := os.Getenv("BOBER_STORE_PATH")
+	if path == "" {
+		return store.NewMemoryStore()
+	}
+
+	bunt, err := store.NewBuntStore(path)
+	if err != nil {
+		logger.Error("не удалось открыть персистентное хранилище задач", "error", err)
+		os.Exit(1)
+	}
+	return bunt
+}
+
+// recoverPendingTasks пересобирает план вычисления для каждой незавершённой
+// задачи, найденной в хранилище при старте, и возвращает её в статус pending,
+// чтобы она была заново разобрана на подзадачи. Это переживает перезапуск
+// оркестратора ценой пересчёта задач, прерванных на середине вычисления, -
+// состояние отдельных подзадач не персистентно.
+func recoverPendingTasks() {
+	tasks, err := taskStore.List()
+	if err != nil {
+		logger.Error("не удалось восстановить задачи", "error", err)
+		return
+	}
+
+	for _, task := range tasks {
+		if task.Status == "completed" {
+			continue
+		}
+
+		plan, err := buildPlan(task.ID, task.Expression)
+		if err != nil {
+			logger.Error("не удалось восстановить задачу", "task_id", task.ID, "error", err)
+			continue
+		}
+		if err := taskStore.UpdateStatus(task.ID, "pending"); err != nil {
+			logger.Error("не удалось восстановить задачу", "task_id", task.ID, "error", err)
+			continue
+		}
+		registerPlan(task.ID, plan)
+		if err := taskScheduler.Push(task.ID, task.ClientID, task.Priority, plan.EstimatedCost()); err != nil {
+			logger.Error("не удалось поставить в очередь восстановленную задачу", "task_id", task.ID, "error", err)
+			continue
+		}
+		metrics.QueueDepth.Set(float64(taskScheduler.Len()))
+		logger.Info("восстановлена незавершённая задача после перезапуска", "task_id", task.ID)
+	}
+}
+
+// runReaper периодически ищет подзадачи, чей воркер перестал присылать
+// heartbeat или чьё выполнение превысило допустимое время, и возвращает их в
+// очередь, чтобы их подхватил другой воркер
+func runReaper() {
+	ticker := time.NewTicker(reaperInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		reapStaleUnits()
+	}
+}
+
+func reapStaleUnits() {
+	staleWorkers := make(map[string]bool)
+	for _, w := range workerRegistry.Stale(staleWorkerTimeout) {
+		staleWorkers[w.ID] = true
+	}
+
+	now := time.Now()
+	execMutex.Lock()
+	var requeued []inFlightUnit
+	for id, inflight := range inFlightUnits {
+		deadline := inflight.startTime.Add(time.Duration(inflight.unit.Duration) * time.Second * unitTimeoutFactor)
+		if staleWorkers[inflight.workerID] || now.After(deadline) {
+			delete(inFlightUnits, id)
+			pendingUnits = append(pendingUnits, inflight.unit)
+			requeued = append(requeued, inflight)
+		}
+	}
+	for id, resolved := range resolvedUnits {
+		if now.Sub(resolved.resolvedAt) > resolvedUnitTTL {
+			delete(resolvedUnits, id)
+		}
+	}
+	execMutex.Unlock()
+
+	for _, inflight := range requeued {
+		logger.Warn("подзадача возвращена в очередь: воркер не отвечает или истёк таймаут",
+			"task_id", inflight.unit.TaskID, "unit_id", inflight.unit.ID, "worker_id", inflight.workerID)
+	}
+}
+
+// route регистрирует обработчик по указанному пути, обёрнутый метриками латентности
+func route(pattern string, handler http.HandlerFunc) {
+	http.HandleFunc(pattern, metrics.Instrument(pattern, handler))
+}
+
 func main() {
+	taskStore = newTaskStore()
+	recoverPendingTasks()
+	go runReaper()
+
 	// Установка обработчиков маршрутов
-	http.HandleFunc("/", indexHandler)
-	http.HandleFunc("/addTask", addTask)
-	http.HandleFunc("/getTaskStatus", getTaskStatus)
-	http.HandleFunc("/getOperations", getOperations)
-	http.HandleFunc("/getTaskForExecution", getTaskForExecution)
-	http.HandleFunc("/handleResult", handleResult)
+	route("/", indexHandler)
+	route("/login", login)
+	route("/addTask", tokenIssuer.Require(addTask, auth.RoleUser))
+	route("/getTaskStatus", tokenIssuer.Require(getTaskStatus, auth.RoleUser))
+	route("/tasks", tokenIssuer.Require(listMyTasks, auth.RoleUser))
+	route("/getOperations", getOperations)
+	route("/getTaskForExecution", tokenIssuer.Require(getTaskForExecution, auth.RoleWorker))
+	route("/handleResult", tokenIssuer.Require(handleResult, auth.RoleWorker))
+	route("/registerWorker", tokenIssuer.Require(registerWorker, auth.RoleWorker))
+	route("/heartbeat", tokenIssuer.Require(heartbeat, auth.RoleWorker))
+	route("/workers", tokenIssuer.Require(listWorkers, auth.RoleUser, auth.RoleWorker))
+	route("/queue", tokenIssuer.Require(listQueue, auth.RoleUser, auth.RoleWorker))
+	http.Handle("/metrics", promhttp.Handler())
 
 	// Запуск веб-сервера на порту 8080
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	logger.Info("оркестратор запущен", "addr", ":8080")
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		logger.Error("веб-сервер остановлен с ошибкой", "error", err)
+		os.Exit(1)
+	}
 }
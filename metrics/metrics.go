@@ -0,0 +1,75 @@
+// Package metrics регистрирует метрики Prometheus оркестратора и
+// HTTP-мидлварь, измеряющую латентность и код ответа каждого обработчика.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// TasksTotal считает задачи по статусу: при создании (pending или сразу
+	// completed) и ещё раз при завершении (completed)
+	TasksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bober_tasks_total",
+		Help: "Общее число задач по статусу",
+	}, []string{"status"})
+
+	// TaskDuration - распределение времени вычисления задачи от StartTime до завершения
+	TaskDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "bober_task_duration_seconds",
+		Help:    "Время выполнения задачи от начала вычисления до завершения",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// QueueDepth - текущее число задач, ожидающих выполнения в планировщике
+	QueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "bober_queue_depth",
+		Help: "Число задач, ожидающих выполнения в очереди планировщика",
+	})
+
+	// WorkersActive - текущее число зарегистрированных воркеров
+	WorkersActive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "bober_workers_active",
+		Help: "Число зарегистрированных воркеров-агентов",
+	})
+
+	// ExpressionParseErrors считает отклонённые addTask из-за невалидного выражения
+	ExpressionParseErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bober_expression_parse_errors_total",
+		Help: "Число выражений, отклонённых при разборе как некорректные",
+	})
+
+	// requestDuration - латентность HTTP-обработчиков по маршруту и коду ответа
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bober_http_request_duration_seconds",
+		Help:    "Латентность HTTP-обработчиков оркестратора",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "status"})
+)
+
+// statusRecorder оборачивает http.ResponseWriter, чтобы запомнить код ответа
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Instrument оборачивает обработчик маршрута route, записывая латентность и
+// код ответа в requestDuration при каждом вызове
+func Instrument(route string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		handler(rec, r)
+		requestDuration.WithLabelValues(route, strconv.Itoa(rec.status)).Observe(time.Since(start).Seconds())
+	}
+}
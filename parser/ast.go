@@ -0,0 +1,21 @@
+package parser
+
+// NodeKind определяет вид узла AST арифметического выражения
+type NodeKind int
+
+const (
+	KindNumber NodeKind = iota
+	KindBinary
+	KindUnaryMinus
+)
+
+// Node представляет узел дерева разбора выражения.
+// Для KindNumber используется только Value, для KindBinary - Operator, Left и Right,
+// для KindUnaryMinus - Operator и Left (операнд унарного минуса).
+type Node struct {
+	Kind     NodeKind
+	Value    float64
+	Operator string
+	Left     *Node
+	Right    *Node
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TokenType определяет тип лексемы арифметического выражения
+type TokenType int
+
+const (
+	TokenNumber TokenType = iota
+	TokenOperator
+	TokenLParen
+	TokenRParen
+	TokenEOF
+)
+
+// Token представляет одну лексему, полученную при разборе выражения
+type Token struct {
+	Type  TokenType
+	Value string
+}
+
+// tokenize разбивает строку выражения на последовательность лексем
+func tokenize(expr string) ([]Token, error) {
+	var tokens []Token
+	runes := []rune(strings.TrimSpace(expr))
+
+	for i := 0; i < len(runes); {
+		ch := runes[i]
+
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '(':
+			tokens = append(tokens, Token{Type: TokenLParen, Value: "("})
+			i++
+		case ch == ')':
+			tokens = append(tokens, Token{Type: TokenRParen, Value: ")"})
+			i++
+		case ch == '+' || ch == '-' || ch == '*' || ch == '/':
+			tokens = append(tokens, Token{Type: TokenOperator, Value: string(ch)})
+			i++
+		case unicode.IsDigit(ch) || ch == '.':
+			start := i
+			seenDot := false
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				if runes[i] == '.' {
+					if seenDot {
+						return nil, fmt.Errorf("некорректное число на позиции %d", start)
+					}
+					seenDot = true
+				}
+				i++
+			}
+			tokens = append(tokens, Token{Type: TokenNumber, Value: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("недопустимый символ %q на позиции %d", ch, i)
+		}
+	}
+
+	tokens = append(tokens, Token{Type: TokenEOF})
+	return tokens, nil
+}
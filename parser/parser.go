@@ -0,0 +1,153 @@
+// Package parser реализует токенизацию и построение AST для арифметических
+// выражений, поддерживающих операторы + - * /, скобки, унарный минус и
+// вещественные литералы. Разбор выполняется Pratt-парсером (операторным
+// парсером с таблицей приоритетов).
+package parser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// precedence задаёт приоритет бинарных операторов: чем выше число, тем раньше
+// выполняется операция.
+var precedence = map[string]int{
+	"+": 1,
+	"-": 1,
+	"*": 2,
+	"/": 2,
+}
+
+// parserState хранит состояние разбора токенов выражения
+type parserState struct {
+	tokens []Token
+	pos    int
+}
+
+// Parse разбирает строку выражения и возвращает корень AST.
+// Возвращает ошибку, если выражение синтаксически некорректно: незакрытые
+// скобки, лишние операторы, деление на литеральный ноль в структуре дерева и т.п.
+func Parse(expr string) (*Node, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 { // только EOF
+		return nil, fmt.Errorf("пустое выражение")
+	}
+
+	p := &parserState{tokens: tokens}
+	node, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.current().Type != TokenEOF {
+		return nil, fmt.Errorf("неожиданный токен %q", p.current().Value)
+	}
+	if err := checkLiteralDivisionByZero(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// checkLiteralDivisionByZero обходит дерево в поисках деления на литеральный
+// ноль (например, "5 / 0"). Деление на подвыражение, которое лишь во время
+// вычисления окажется нулём (например, "5 / (1 - 1)"), этой проверкой не
+// ловится - это была бы уже не синтаксическая, а семантическая проверка.
+func checkLiteralDivisionByZero(n *Node) error {
+	switch n.Kind {
+	case KindBinary:
+		if n.Operator == "/" && n.Right.Kind == KindNumber && n.Right.Value == 0 {
+			return fmt.Errorf("деление на ноль")
+		}
+		if err := checkLiteralDivisionByZero(n.Left); err != nil {
+			return err
+		}
+		return checkLiteralDivisionByZero(n.Right)
+	case KindUnaryMinus:
+		return checkLiteralDivisionByZero(n.Left)
+	default:
+		return nil
+	}
+}
+
+func (p *parserState) current() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parserState) advance() Token {
+	tok := p.tokens[p.pos]
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return tok
+}
+
+// parseExpression разбирает выражение с учётом приоритетов операторов
+// (алгоритм Pratt-парсера): minPrecedence ограничивает, какие операторы
+// допустимо "забрать" на текущем уровне рекурсии.
+func (p *parserState) parseExpression(minPrecedence int) (*Node, error) {
+	left, err := p.parsePrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.current()
+		if tok.Type != TokenOperator {
+			break
+		}
+		prec, ok := precedence[tok.Value]
+		if !ok || prec < minPrecedence {
+			break
+		}
+
+		p.advance()
+		right, err := p.parseExpression(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &Node{Kind: KindBinary, Operator: tok.Value, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// parsePrefix разбирает первичное выражение: число, унарный минус или
+// выражение в скобках.
+func (p *parserState) parsePrefix() (*Node, error) {
+	tok := p.current()
+
+	switch {
+	case tok.Type == TokenNumber:
+		p.advance()
+		value, err := strconv.ParseFloat(tok.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное число %q", tok.Value)
+		}
+		return &Node{Kind: KindNumber, Value: value}, nil
+
+	case tok.Type == TokenOperator && tok.Value == "-":
+		p.advance()
+		operand, err := p.parseExpression(precedence["*"])
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: KindUnaryMinus, Operator: "-", Left: operand}, nil
+
+	case tok.Type == TokenLParen:
+		p.advance()
+		node, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		if p.current().Type != TokenRParen {
+			return nil, fmt.Errorf("не хватает закрывающей скобки")
+		}
+		p.advance()
+		return node, nil
+
+	default:
+		return nil, fmt.Errorf("неожиданный токен %q", tok.Value)
+	}
+}
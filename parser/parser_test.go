@@ -0,0 +1,87 @@
+package parser
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	node, err := Parse("42")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	if node.Kind != KindNumber || node.Value != 42 {
+		t.Fatalf("ожидался узел KindNumber со значением 42, получили %+v", node)
+	}
+}
+
+func TestParseUnaryMinus(t *testing.T) {
+	node, err := Parse("-5")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	if node.Kind != KindUnaryMinus || node.Operator != "-" {
+		t.Fatalf("ожидался узел KindUnaryMinus, получили %+v", node)
+	}
+	if node.Left == nil || node.Left.Kind != KindNumber || node.Left.Value != 5 {
+		t.Fatalf("ожидался операнд 5, получили %+v", node.Left)
+	}
+}
+
+func TestParsePrecedenceAndParens(t *testing.T) {
+	// 2 + 3 * 4 - умножение должно быть глубже по дереву, чем сложение
+	node, err := Parse("2 + 3 * 4")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	if node.Kind != KindBinary || node.Operator != "+" {
+		t.Fatalf("корнем должен быть +, получили %+v", node)
+	}
+	if node.Right.Kind != KindBinary || node.Right.Operator != "*" {
+		t.Fatalf("правым поддеревом должно быть умножение, получили %+v", node.Right)
+	}
+
+	// (2 + 3) * 4 - скобки должны поднять сложение выше умножения
+	node, err = Parse("(2 + 3) * 4")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	if node.Kind != KindBinary || node.Operator != "*" {
+		t.Fatalf("корнем должен быть *, получили %+v", node)
+	}
+	if node.Left.Kind != KindBinary || node.Left.Operator != "+" {
+		t.Fatalf("левым поддеревом должно быть сложение, получили %+v", node.Left)
+	}
+}
+
+func TestParseNestedParens(t *testing.T) {
+	node, err := Parse("((1 - 2) * (3 + 4))")
+	if err != nil {
+		t.Fatalf("Parse вернул ошибку: %v", err)
+	}
+	if node.Kind != KindBinary || node.Operator != "*" {
+		t.Fatalf("корнем должно быть умножение, получили %+v", node)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"",       // пустое выражение
+		"1 +",    // оператор без правого операнда
+		"(1 + 2", // не хватает закрывающей скобки
+		"1 + 2)", // лишняя закрывающая скобка
+		"1..2",   // некорректное число
+		"1 $ 2",  // недопустимый символ
+		"5 / 0",  // деление на литеральный ноль
+	}
+	for _, expr := range cases {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) = nil error, ожидалась ошибка", expr)
+		}
+	}
+}
+
+func TestParseAllowsDivisionByNonLiteralZero(t *testing.T) {
+	// "1 - 1" вычисляется в ноль только во время исполнения, а не в структуре
+	// дерева - это не ловится проверкой деления на литеральный ноль
+	if _, err := Parse("5 / (1 - 1)"); err != nil {
+		t.Fatalf("Parse не должен отклонять деление на невычисленное подвыражение: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package scheduler реализует очередь ожидающих задач на основе кучи
+// (heap), упорядоченную по весу из явного приоритета заявки, времени подачи,
+// оценочной стоимости выражения и честности между клиентами. Веса этих
+// факторов заданы константами пакета и во время работы не меняются.
+package scheduler
+
+import (
+	"container/heap"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded возвращается Push, если у клиента уже стоит в очереди
+// максимально допустимое число заявок
+var ErrQuotaExceeded = errors.New("превышена квота на одновременные заявки клиента")
+
+const (
+	priorityWeight = 1e6 // явный приоритет - главный фактор веса
+	fairnessWeight = 1e3 // штраф за каждую другую задачу того же клиента, уже стоящую в очереди
+	costWeight     = 1   // штраф за каждую секунду оценочной стоимости выражения
+)
+
+// Entry - задача, ожидающая выполнения, вместе с параметрами, определившими её вес в очереди
+type Entry struct {
+	TaskID        string    `json:"task_id"`
+	ClientID      string    `json:"client_id"`
+	Priority      int       `json:"priority"`
+	EstimatedCost int       `json:"estimated_cost"` // сумма Duration операций выражения, в секундах
+	SubmittedAt   time.Time `json:"submitted_at"`
+	Score         float64   `json:"score"` // итоговый вес: меньше - выполняется раньше
+}
+
+// score считает вес заявки: выше Priority, меньше задач клиента уже в
+// очереди и меньше оценочная стоимость - тем меньше score и тем раньше заявка
+// будет выполнена. Время подачи (в секундах) добавляется к весу, так что при
+// прочих равных раньше поданная заявка выполняется раньше.
+func score(priority int, sameClientAhead int, estimatedCost int, submittedAt time.Time) float64 {
+	return -float64(priority)*priorityWeight +
+		float64(sameClientAhead)*fairnessWeight +
+		float64(estimatedCost)*costWeight +
+		float64(submittedAt.UnixNano())/1e9
+}
+
+// before задаёт порядок выполнения: меньший Score раньше, а при равном Score -
+// раньше поданная заявка (FIFO)
+func before(a, b *Entry) bool {
+	if a.Score != b.Score {
+		return a.Score < b.Score
+	}
+	return a.SubmittedAt.Before(b.SubmittedAt)
+}
+
+// entryHeap - куча *Entry, реализующая container/heap.Interface
+type entryHeap []*Entry
+
+func (h entryHeap) Len() int            { return len(h) }
+func (h entryHeap) Less(i, j int) bool  { return before(h[i], h[j]) }
+func (h entryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *entryHeap) Push(x interface{}) { *h = append(*h, x.(*Entry)) }
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	*h = old[:n-1]
+	return entry
+}
+
+// Scheduler - потокобезопасная очередь ожидающих задач с честным
+// распределением между клиентами и опциональными квотами
+type Scheduler struct {
+	mu          sync.Mutex
+	queue       entryHeap
+	clientCount map[string]int // сколько заявок клиента сейчас стоит в очереди
+	quota       int            // максимум одновременных заявок от одного клиента, 0 - без ограничения
+}
+
+// NewScheduler создаёт пустую очередь. quota <= 0 отключает ограничение на клиента.
+func NewScheduler(quota int) *Scheduler {
+	return &Scheduler{clientCount: make(map[string]int), quota: quota}
+}
+
+// Push ставит задачу в очередь. Возвращает ErrQuotaExceeded, если клиент
+// превысил квоту одновременных заявок.
+func (s *Scheduler) Push(taskID, clientID string, priority, estimatedCost int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quota > 0 && s.clientCount[clientID] >= s.quota {
+		return ErrQuotaExceeded
+	}
+
+	entry := &Entry{
+		TaskID:        taskID,
+		ClientID:      clientID,
+		Priority:      priority,
+		EstimatedCost: estimatedCost,
+		SubmittedAt:   time.Now(),
+	}
+	entry.Score = score(priority, s.clientCount[clientID], estimatedCost, entry.SubmittedAt)
+
+	heap.Push(&s.queue, entry)
+	s.clientCount[clientID]++
+	return nil
+}
+
+// Pop забирает задачу с наименьшим весом (наивысшим приоритетом). Второе
+// возвращаемое значение - false, если очередь пуста.
+func (s *Scheduler) Pop() (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queue.Len() == 0 {
+		return Entry{}, false
+	}
+
+	entry := heap.Pop(&s.queue).(*Entry)
+	s.clientCount[entry.ClientID]--
+	if s.clientCount[entry.ClientID] <= 0 {
+		delete(s.clientCount, entry.ClientID)
+	}
+	return *entry, true
+}
+
+// Len возвращает число задач, ожидающих выполнения в очереди
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue.Len()
+}
+
+// Snapshot возвращает ожидающие задачи в порядке выполнения для наблюдаемости (GET /queue)
+func (s *Scheduler) Snapshot() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, len(s.queue))
+	for i, e := range s.queue {
+		entries[i] = *e
+	}
+	sort.Slice(entries, func(i, j int) bool { return before(&entries[i], &entries[j]) })
+	return entries
+}
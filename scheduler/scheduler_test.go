@@ -0,0 +1,61 @@
+package scheduler
+
+import "testing"
+
+func TestPopOrdersByPriority(t *testing.T) {
+	s := NewScheduler(0)
+	mustPush(t, s, "low", "client-a", 0, 1)
+	mustPush(t, s, "high", "client-a", 5, 1)
+	mustPush(t, s, "mid", "client-a", 1, 1)
+
+	wantOrder := []string{"high", "mid", "low"}
+	for _, want := range wantOrder {
+		entry, ok := s.Pop()
+		if !ok {
+			t.Fatalf("Pop вернул ok=false, ожидалась задача %q", want)
+		}
+		if entry.TaskID != want {
+			t.Fatalf("Pop вернул %q, ожидалась %q", entry.TaskID, want)
+		}
+	}
+	if _, ok := s.Pop(); ok {
+		t.Fatalf("очередь должна быть пуста")
+	}
+}
+
+func TestPopFairnessBetweenClients(t *testing.T) {
+	s := NewScheduler(0)
+	// У client-a уже две задачи в очереди к моменту, когда подаёт client-b -
+	// честность должна пропустить задачу client-b вперёд третьей задачи client-a
+	mustPush(t, s, "a1", "client-a", 0, 1)
+	mustPush(t, s, "a2", "client-a", 0, 1)
+	mustPush(t, s, "b1", "client-b", 0, 1)
+	mustPush(t, s, "a3", "client-a", 0, 1)
+
+	entry, ok := s.Pop()
+	if !ok || entry.TaskID != "a1" {
+		t.Fatalf("первой должна выйти a1 (FIFO при равном приоритете), получили %+v ok=%v", entry, ok)
+	}
+	entry, ok = s.Pop()
+	if !ok || entry.TaskID != "b1" {
+		t.Fatalf("b1 должна выйти раньше a2/a3 из-за штрафа за очередь client-a, получили %+v ok=%v", entry, ok)
+	}
+}
+
+func TestPushQuotaExceeded(t *testing.T) {
+	s := NewScheduler(1)
+	mustPush(t, s, "t1", "client-a", 0, 1)
+	if err := s.Push("t2", "client-a", 0, 1); err != ErrQuotaExceeded {
+		t.Fatalf("ожидалась ErrQuotaExceeded, получили %v", err)
+	}
+	if err := s.Push("t3", "client-b", 0, 1); err != nil {
+		t.Fatalf("квота одного клиента не должна блокировать другого, получили %v", err)
+	}
+}
+
+func mustPush(t *testing.T, s *Scheduler, taskID, clientID string, priority, cost int) {
+	t.Helper()
+	if err := s.Push(taskID, clientID, priority, cost); err != nil {
+		t.Fatalf("Push(%q) вернул ошибку: %v", taskID, err)
+	}
+}
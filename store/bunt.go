@@ -0,0 +1,121 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tidwall/buntdb"
+)
+
+// BuntStore - персистентная реализация TaskStore поверх встраиваемой
+// key-value базы BuntDB: задачи переживают перезапуск оркестратора
+type BuntStore struct {
+	db *buntdb.DB
+}
+
+// NewBuntStore открывает (создавая при отсутствии) файл базы данных BuntDB по указанному пути
+func NewBuntStore(path string) (*BuntStore, error) {
+	db, err := buntdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть хранилище задач: %w", err)
+	}
+	return &BuntStore{db: db}, nil
+}
+
+// Close закрывает файл базы данных
+func (s *BuntStore) Close() error {
+	return s.db.Close()
+}
+
+func taskKey(id string) string {
+	return "task:" + id
+}
+
+func (s *BuntStore) Create(task Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(taskKey(task.ID), string(data), nil)
+		return err
+	})
+}
+
+func (s *BuntStore) Get(id string) (Task, error) {
+	var task Task
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(taskKey(id))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrNotFound
+			}
+			return err
+		}
+		return json.Unmarshal([]byte(val), &task)
+	})
+	return task, err
+}
+
+func (s *BuntStore) List() ([]Task, error) {
+	var tasks []Task
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys("task:*", func(_, val string) bool {
+			var task Task
+			if jsonErr := json.Unmarshal([]byte(val), &task); jsonErr == nil {
+				tasks = append(tasks, task)
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+	return tasks, nil
+}
+
+func (s *BuntStore) UpdateStatus(id, status string) error {
+	return s.update(id, func(task *Task) {
+		task.Status = status
+		if status == "in_progress" && task.StartTime.IsZero() {
+			task.StartTime = time.Now()
+		}
+	})
+}
+
+func (s *BuntStore) SetResult(id string, result float64) error {
+	return s.update(id, func(task *Task) {
+		task.Result = result
+		task.Status = "completed"
+	})
+}
+
+// update читает задачу, применяет mutate и сохраняет её в одной транзакции
+func (s *BuntStore) update(id string, mutate func(task *Task)) error {
+	return s.db.Update(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(taskKey(id))
+		if err != nil {
+			if err == buntdb.ErrNotFound {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		var task Task
+		if err := json.Unmarshal([]byte(val), &task); err != nil {
+			return err
+		}
+		mutate(&task)
+
+		data, err := json.Marshal(task)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(taskKey(id), string(data), nil)
+		return err
+	})
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore - потокобезопасная реализация TaskStore поверх map, без
+// персистентности: все задачи теряются при перезапуске процесса
+type MemoryStore struct {
+	mu    sync.Mutex
+	tasks map[string]Task
+	order []string // порядок поступления задач, нужен для List
+}
+
+// NewMemoryStore создаёт пустое хранилище задач в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tasks: make(map[string]Task)}
+}
+
+func (s *MemoryStore) Create(task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[task.ID]; exists {
+		return fmt.Errorf("задача %s уже существует", task.ID)
+	}
+	s.tasks[task.ID] = task
+	s.order = append(s.order, task.ID)
+	return nil
+}
+
+func (s *MemoryStore) Get(id string) (Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return task, nil
+}
+
+func (s *MemoryStore) List() ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Task, 0, len(s.order))
+	for _, id := range s.order {
+		if task, ok := s.tasks[id]; ok {
+			result = append(result, task)
+		}
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) UpdateStatus(id, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return ErrNotFound
+	}
+	task.Status = status
+	if status == "in_progress" && task.StartTime.IsZero() {
+		task.StartTime = time.Now()
+	}
+	s.tasks[id] = task
+	return nil
+}
+
+func (s *MemoryStore) SetResult(id string, result float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, ok := s.tasks[id]
+	if !ok {
+		return ErrNotFound
+	}
+	task.Result = result
+	task.Status = "completed"
+	s.tasks[id] = task
+	return nil
+}
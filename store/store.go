@@ -0,0 +1,40 @@
+// Package store абстрагирует хранение задач оркестратора за интерфейсом
+// TaskStore, позволяя подменять бэкенд (в памяти или персистентный) без
+// изменения HTTP-обработчиков.
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound возвращается, если задача с указанным ID не найдена
+var ErrNotFound = errors.New("задача не найдена")
+
+// Task представляет сохраняемое состояние вычисляемого выражения
+type Task struct {
+	ID         string    `json:"id"`
+	Expression string    `json:"expression"`
+	Status     string    `json:"status"` // pending, in_progress, completed
+	Result     float64   `json:"result,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	StartTime  time.Time `json:"start_time,omitempty"`
+	ClientID   string    `json:"client_id,omitempty"` // кто отправил задачу - используется планировщиком для честного распределения
+	Priority   int       `json:"priority,omitempty"`  // явный приоритет, заданный клиентом
+	OwnerID    string    `json:"owner_id,omitempty"`  // subject токена, создавшего задачу - только он может видеть её статус
+}
+
+// TaskStore - хранилище задач. Методы должны быть потокобезопасны, так как
+// вызываются параллельно из разных HTTP-обработчиков.
+type TaskStore interface {
+	// Create сохраняет новую задачу
+	Create(task Task) error
+	// Get возвращает задачу по ID или ErrNotFound
+	Get(id string) (Task, error)
+	// List возвращает все задачи в порядке их создания
+	List() ([]Task, error)
+	// UpdateStatus обновляет статус задачи
+	UpdateStatus(id, status string) error
+	// SetResult сохраняет результат вычисления и переводит задачу в статус completed
+	SetResult(id string, result float64) error
+}
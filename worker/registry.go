@@ -0,0 +1,107 @@
+// Package worker отслеживает зарегистрированных воркеров-агентов, их
+// heartbeat-сигналы и то, какую подзадачу каждый из них сейчас выполняет,
+// чтобы можно было обнаружить упавшего воркера и переназначить его работу.
+package worker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnknownWorker возвращается, если воркер с указанным ID не регистрировался
+var ErrUnknownWorker = errors.New("неизвестный воркер")
+
+// Worker представляет зарегистрированного воркера-агента
+type Worker struct {
+	ID            string    `json:"id"`
+	RegisteredAt  time.Time `json:"registered_at"`
+	LastHeartbeat time.Time `json:"last_heartbeat"`
+	CurrentUnit   string    `json:"current_unit,omitempty"`
+}
+
+// Registry - потокобезопасный реестр зарегистрированных воркеров. Упавшие
+// воркеры из него не удаляются - их находит Stale
+type Registry struct {
+	mu      sync.Mutex
+	workers map[string]*Worker
+}
+
+// NewRegistry создаёт пустой реестр воркеров
+func NewRegistry() *Registry {
+	return &Registry{workers: make(map[string]*Worker)}
+}
+
+// Register регистрирует нового воркера и возвращает выданный ему ID
+func (r *Registry) Register() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := uuid.NewString()
+	now := time.Now()
+	r.workers[id] = &Worker{ID: id, RegisteredAt: now, LastHeartbeat: now}
+	return id
+}
+
+// Known сообщает, зарегистрирован ли воркер с таким ID
+func (r *Registry) Known(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.workers[id]
+	return ok
+}
+
+// Heartbeat обновляет время последнего сигнала живости воркера
+func (r *Registry) Heartbeat(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.workers[id]
+	if !ok {
+		return ErrUnknownWorker
+	}
+	w.LastHeartbeat = time.Now()
+	return nil
+}
+
+// SetCurrentUnit запоминает, какую подзадачу сейчас выполняет воркер.
+// Пустой unitID означает, что воркер свободен.
+func (r *Registry) SetCurrentUnit(id, unitID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if w, ok := r.workers[id]; ok {
+		w.CurrentUnit = unitID
+	}
+}
+
+// List возвращает снимок состояния всех известных воркеров
+func (r *Registry) List() []Worker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]Worker, 0, len(r.workers))
+	for _, w := range r.workers {
+		result = append(result, *w)
+	}
+	return result
+}
+
+// Stale возвращает воркеров, не присылавших heartbeat дольше timeout -
+// такие воркеры считаются упавшими
+func (r *Registry) Stale(timeout time.Duration) []Worker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := time.Now().Add(-timeout)
+	var stale []Worker
+	for _, w := range r.workers {
+		if w.LastHeartbeat.Before(cutoff) {
+			stale = append(stale, *w)
+		}
+	}
+	return stale
+}